@@ -0,0 +1,228 @@
+package avatarbuilder
+
+import (
+	"container/list"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"sync"
+
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// defaultAtlasRunes is the rune set NewAtlasBuilder prerasterizes when none is given.
+var defaultAtlasRunes = []rune("ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789")
+
+// defaultAtlasCacheSize bounds how many fully-rendered avatars GenerateImageFast keeps in memory.
+const defaultAtlasCacheSize = 256
+
+// glyph is a prerasterized mask plus the metrics needed to place it on a line.
+type glyph struct {
+	mask    *image.Alpha
+	bearing image.Point
+	advance int
+}
+
+// AtlasBuilder prerasterizes a fixed rune set at construction time so
+// GenerateImageFast can composite glyph masks directly via draw.DrawMask
+// instead of running freetype's rasterizer on every call. Glyphs are placed
+// without hinting or kerning, trading layout quality for throughput, so it's
+// meant for high-QPS initials rendering; GenerateImage remains the default
+// slow path, which applies kerning (it renders unhinted as well).
+type AtlasBuilder struct {
+	glyphs map[rune]glyph
+	ascent int
+	cache  *avatarCache
+}
+
+// NewAtlasBuilder prerasterizes runes (defaultAtlasRunes if empty) from src at
+// the given size. Runes missing from the font are silently skipped; GenerateImageFast
+// skips them too when compositing.
+func NewAtlasBuilder(runes []rune, src FontSource, size float64) (*AtlasBuilder, error) {
+	if len(runes) == 0 {
+		runes = defaultAtlasRunes
+	}
+
+	f, err := src.Font()
+	if err != nil {
+		return nil, err
+	}
+
+	face := truetype.NewFace(f, &truetype.Options{Size: size, DPI: 72, Hinting: font.HintingNone})
+	defer face.Close()
+
+	atlas := &AtlasBuilder{
+		glyphs: make(map[rune]glyph, len(runes)),
+		ascent: face.Metrics().Ascent.Ceil(),
+		cache:  newAvatarCache(defaultAtlasCacheSize),
+	}
+	for _, r := range runes {
+		if g, ok := rasterizeGlyph(face, r); ok {
+			atlas.glyphs[r] = g
+		}
+	}
+
+	return atlas, nil
+}
+
+// rasterizeGlyph rasterizes r at the origin and copies its coverage mask out
+// of face's internal buffer, since font.Face.Glyph reuses that buffer on the
+// next call.
+func rasterizeGlyph(face font.Face, r rune) (glyph, bool) {
+	dr, mask, maskp, advance, ok := face.Glyph(fixed.Point26_6{}, r)
+	if !ok || dr.Empty() {
+		return glyph{}, false
+	}
+
+	alpha := image.NewAlpha(image.Rect(0, 0, dr.Dx(), dr.Dy()))
+	draw.Draw(alpha, alpha.Bounds(), mask, maskp, draw.Src)
+
+	return glyph{mask: alpha, bearing: dr.Min, advance: advance.Ceil()}, true
+}
+
+// startPen returns the starting pen position (left edge, baseline) that centers s (the glyphs
+// present in the atlas, at least) within a w x h avatar.
+func (atlas *AtlasBuilder) startPen(s string, w, h int) image.Point {
+	var total int
+	for _, r := range s {
+		if g, ok := atlas.glyphs[r]; ok {
+			total += g.advance
+		}
+	}
+
+	return image.Point{X: (w - total) / 2, Y: (h + atlas.ascent) / 2}
+}
+
+// SetAtlas configures the AtlasBuilder used by GenerateImageFast. There is no
+// default; GenerateImageFast errors until this is called.
+func (ab *AvatarBuilder) SetAtlas(atlas *AtlasBuilder) {
+	ab.atlas = atlas
+}
+
+// GenerateImageFast renders s by compositing ab.atlas's prerasterized glyph
+// masks instead of calling freetype.Context.DrawString, and serves repeat
+// (s, fg, bg, size, W, H) combinations straight from an LRU cache. Requires
+// SetAtlas to have been called first.
+func (ab *AvatarBuilder) GenerateImageFast(s string) ([]byte, error) {
+	if ab.atlas == nil {
+		return nil, errors.New("generate image fast: no atlas set, call SetAtlas first")
+	}
+
+	key := avatarCacheKey{s: s, fg: normalizeColor(ab.fg), bg: normalizeColor(ab.bg), size: ab.fontSize, w: ab.W, h: ab.H}
+	if cached, ok := ab.atlas.cache.get(key); ok {
+		return cached, nil
+	}
+
+	rgba := ab.buildColorImage()
+	fg := image.NewUniform(ab.fg)
+	pen := ab.atlas.startPen(s, ab.W, ab.H)
+
+	for _, r := range s {
+		g, ok := ab.atlas.glyphs[r]
+		if !ok {
+			continue
+		}
+
+		dr := g.mask.Bounds().Add(image.Point{X: pen.X + g.bearing.X, Y: pen.Y + g.bearing.Y})
+		draw.DrawMask(rgba, dr, fg, image.Point{}, g.mask, image.Point{}, draw.Over)
+		pen.X += g.advance
+	}
+
+	ab.applyShape(rgba)
+
+	bs, err := ab.encode(rgba)
+	if err != nil {
+		return nil, err
+	}
+
+	ab.atlas.cache.set(key, bs)
+	return bs, nil
+}
+
+// avatarCacheKey identifies a fully-rendered avatar. fg/bg are normalized to
+// color.RGBA via normalizeColor so two color.Color values that represent the
+// same color (e.g. two distinct *color.RGBA pointers from hexToRGBA) compare
+// equal as map keys.
+type avatarCacheKey struct {
+	s    string
+	fg   color.RGBA
+	bg   color.RGBA
+	size float64
+	w, h int
+}
+
+// normalizeColor converts c to a plain, comparable color.RGBA value so
+// cache keys don't depend on which concrete type or pointer identity
+// produced the color.
+func normalizeColor(c color.Color) color.RGBA {
+	return color.RGBAModel.Convert(c).(color.RGBA)
+}
+
+// avatarCache is a small LRU cache of encoded avatar bytes keyed by
+// avatarCacheKey. It's guarded by mu since GenerateImageFast is the
+// high-QPS path and expected to be called concurrently across goroutines
+// sharing one AtlasBuilder.
+type avatarCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[avatarCacheKey]*list.Element
+}
+
+type avatarCacheEntry struct {
+	key   avatarCacheKey
+	value []byte
+}
+
+func newAvatarCache(capacity int) *avatarCache {
+	return &avatarCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[avatarCacheKey]*list.Element),
+	}
+}
+
+func (c *avatarCache) get(key avatarCacheKey) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return el.Value.(*avatarCacheEntry).value, true
+}
+
+func (c *avatarCache) set(key avatarCacheKey, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		el.Value.(*avatarCacheEntry).value = value
+		return
+	}
+
+	el := c.ll.PushFront(&avatarCacheEntry{key: key, value: value})
+	c.items[key] = el
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*avatarCacheEntry).key)
+	}
+}
+
+// len reports the number of entries currently cached, guarded by mu so tests
+// can inspect cache size without racing concurrent get/set calls.
+func (c *avatarCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
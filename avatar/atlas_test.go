@@ -0,0 +1,58 @@
+package avatarbuilder
+
+import (
+	"sync"
+	"testing"
+
+	"golang.org/x/image/font/gofont/goregular"
+)
+
+func TestGenerateImageFastCacheKeyNormalizesHexColor(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	atlas, err := NewAtlasBuilder(nil, embeddedFontSource{data: goregular.TTF}, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab.SetAtlas(atlas)
+
+	ab.SetBackGroundColorHex(0x112233)
+	if _, err := ab.GenerateImageFast("AB"); err != nil {
+		t.Fatal(err)
+	}
+	ab.SetBackGroundColorHex(0x112233)
+	if _, err := ab.GenerateImageFast("AB"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := atlas.cache.len(); got != 1 {
+		t.Fatalf("expected 1 cache entry for two calls with the identical hex background, got %d", got)
+	}
+}
+
+func TestGenerateImageFastConcurrentCallsDontRace(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	atlas, err := NewAtlasBuilder(nil, embeddedFontSource{data: goregular.TTF}, 80)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ab.SetAtlas(atlas)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 50)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s := string(rune('A' + i%26))
+			if _, err := ab.GenerateImageFast(s); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
@@ -2,56 +2,118 @@ package avatarbuilder
 
 import (
 	"bufio"
-	"bytes"
 	"errors"
 	"image"
 	"image/color"
 	"image/draw"
-	"image/png"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 
 	"github.com/golang/freetype"
+	"github.com/golang/freetype/truetype"
 	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/goregular"
 )
 
+// FontSource supplies the font used to draw an avatar. Implementations let
+// AvatarBuilder load a font from disk, from embedded bytes, or from any other
+// origin without buildDrawContext knowing the difference.
+type FontSource interface {
+	Font() (*truetype.Font, error)
+}
+
+// fileFontSource reads and parses a TTF file from disk on every call.
+type fileFontSource struct {
+	path string
+}
+
+func (s fileFontSource) Font() (*truetype.Font, error) {
+	fontBytes, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return nil, errors.New("error when open font file:" + err.Error())
+	}
+
+	f, err := freetype.ParseFont(fontBytes)
+	if err != nil {
+		return nil, errors.New("error when parse font file:" + err.Error())
+	}
+
+	return f, nil
+}
+
+// embeddedFontSource parses a font from bytes baked into the binary.
+type embeddedFontSource struct {
+	data []byte
+}
+
+func (s embeddedFontSource) Font() (*truetype.Font, error) {
+	f, err := truetype.Parse(s.data)
+	if err != nil {
+		return nil, errors.New("error when parse embedded font:" + err.Error())
+	}
+
+	return f, nil
+}
+
 type FontCenterCalculator interface {
 	// CalculateCenterLocation used to calculate center location in different font style
 	CalculateCenterLocation(string, *AvatarBuilder) (int, int)
 }
 
 const (
-	defaultHigh  = 200
-	defaultWidth = 200
-	defaultFont  = 80
+	defaultHigh               = 200
+	defaultWidth              = 200
+	defaultFont               = 80
+	defaultTextPadding        = 10
+	defaultLineHeightMultiple = 1.5
 )
 
 type AvatarBuilder struct {
-	W        int
-	H        int
-	fontFile string
-	fontSize float64
-	bg       color.Color
-	fg       color.Color
-	ctx      *freetype.Context
-	calc     FontCenterCalculator
+	W                  int
+	H                  int
+	fontSource         FontSource
+	fontSize           float64
+	bg                 color.Color
+	fg                 color.Color
+	ctx                *freetype.Context
+	font               *truetype.Font
+	calc               FontCenterCalculator
+	palette            []color.Color
+	textPadding        int
+	lineHeightMultiple float64
+	encoder            Encoder
+	shape              Shape
+	borderWidth        int
+	borderColor        color.Color
+	atlas              *AtlasBuilder
 }
 
 func NewAvatarBuilder(fontFile string, calc FontCenterCalculator) *AvatarBuilder {
 	ab := &AvatarBuilder{}
-	ab.fontFile = fontFile
+	ab.fontSource = fileFontSource{path: fontFile}
 	ab.bg, ab.fg = color.White, color.Black
 	ab.W, ab.H = defaultHigh, defaultWidth
 	ab.fontSize = defaultFont
+	ab.textPadding = defaultTextPadding
+	ab.lineHeightMultiple = defaultLineHeightMultiple
+	ab.encoder = PNGEncoder{}
 	ab.calc = calc
 
 	return ab
 }
 
+// BuilderOption configures an AvatarBuilder at construction time, for use
+// with NewAvatarBuilderWithOption.
+type BuilderOption func(*AvatarBuilder)
+
 func NewAvatarBuilderWithOption(fontFile string, calc FontCenterCalculator, opt ...BuilderOption) *AvatarBuilder {
 	ab := &AvatarBuilder{}
-	ab.fontFile = fontFile
+	ab.fontSource = fileFontSource{path: fontFile}
 	ab.bg, ab.fg = color.White, color.Black
+	ab.textPadding = defaultTextPadding
+	ab.lineHeightMultiple = defaultLineHeightMultiple
+	ab.encoder = PNGEncoder{}
 	for _, f := range opt {
 		f(ab)
 	}
@@ -60,6 +122,23 @@ func NewAvatarBuilderWithOption(fontFile string, calc FontCenterCalculator, opt
 	return ab
 }
 
+// NewAvatarBuilderWithEmbeddedFont builds an AvatarBuilder backed by the
+// goregular font baked into the binary, so callers don't need to ship a TTF
+// file alongside unit tests, serverless functions, or single-binary deploys.
+func NewAvatarBuilderWithEmbeddedFont(calc FontCenterCalculator) *AvatarBuilder {
+	ab := &AvatarBuilder{}
+	ab.fontSource = embeddedFontSource{data: goregular.TTF}
+	ab.bg, ab.fg = color.White, color.Black
+	ab.W, ab.H = defaultHigh, defaultWidth
+	ab.fontSize = defaultFont
+	ab.textPadding = defaultTextPadding
+	ab.lineHeightMultiple = defaultLineHeightMultiple
+	ab.encoder = PNGEncoder{}
+	ab.calc = calc
+
+	return ab
+}
+
 func (ab *AvatarBuilder) SetFrontGroundColor(c color.Color) {
 	ab.fg = c
 }
@@ -85,12 +164,34 @@ func (ab *AvatarBuilder) SetAvatarSize(w int, h int) {
 	ab.H = h
 }
 
+// SetTextPadding sets the horizontal margin, in pixels, reserved on each side
+// of the avatar when word-wrapping multiline text.
+func (ab *AvatarBuilder) SetTextPadding(padding int) {
+	ab.textPadding = padding
+}
+
+// SetLineHeightMultiple sets the line spacing used by GenerateMultilineImage,
+// expressed as a multiple of the current font size.
+func (ab *AvatarBuilder) SetLineHeightMultiple(multiple float64) {
+	ab.lineHeightMultiple = multiple
+}
+
+// GenerateImageAndSave renders s and writes it to outName. If outName has no
+// file extension, one is appended based on ab's encoder (PNGEncoder{} by default).
 func (ab *AvatarBuilder) GenerateImageAndSave(s string, outName string) error {
 	bs, err := ab.GenerateImage(s)
 	if err != nil {
 		return err
 	}
 
+	if filepath.Ext(outName) == "" {
+		enc := ab.encoder
+		if enc == nil {
+			enc = PNGEncoder{}
+		}
+		outName += enc.Extension()
+	}
+
 	// Save that RGBA image to disk.
 	outFile, err := os.Create(outName)
 	if err != nil {
@@ -112,10 +213,8 @@ func (ab *AvatarBuilder) GenerateImageAndSave(s string, outName string) error {
 
 func (ab *AvatarBuilder) GenerateImage(s string) ([]byte, error) {
 	rgba := ab.buildColorImage()
-	if ab.ctx == nil {
-		if err := ab.buildDrawContext(rgba); err != nil {
-			return nil, err
-		}
+	if err := ab.buildDrawContext(rgba); err != nil {
+		return nil, err
 	}
 
 	x, y := ab.calc.CalculateCenterLocation(s, ab)
@@ -124,12 +223,9 @@ func (ab *AvatarBuilder) GenerateImage(s string) ([]byte, error) {
 		return nil, errors.New("draw string: " + err.Error())
 	}
 
-	buf := &bytes.Buffer{}
-	if err := png.Encode(buf, rgba); err != nil {
-		return nil, errors.New("png encode: " + err.Error())
-	}
+	ab.applyShape(rgba)
 
-	return buf.Bytes(), nil
+	return ab.encode(rgba)
 }
 
 func (ab *AvatarBuilder) buildColorImage() *image.RGBA {
@@ -150,16 +246,30 @@ func (ab *AvatarBuilder) hexToRGBA(h uint32) *color.RGBA {
 	return rgba
 }
 
-func (ab *AvatarBuilder) buildDrawContext(rgba *image.RGBA) error {
-	// Read the font data.
-	fontBytes, err := ioutil.ReadFile(ab.fontFile)
-	if err != nil {
-		return errors.New("error when open font file:" + err.Error())
+// loadFont parses ab.fontSource once and caches the result on ab.font, so
+// repeated calls to GenerateImage/GenerateMultilineImage on the same builder
+// don't re-read a font file or re-parse embedded font bytes every time.
+func (ab *AvatarBuilder) loadFont() (*truetype.Font, error) {
+	if ab.font == nil {
+		f, err := ab.fontSource.Font()
+		if err != nil {
+			return nil, err
+		}
+		ab.font = f
 	}
 
-	f, err := freetype.ParseFont(fontBytes)
+	return ab.font, nil
+}
+
+// buildDrawContext (re)builds ab.ctx for rgba. It runs on every call to
+// GenerateImage/GenerateMultilineImage: freetype.Context caches its dst and
+// src internally, so reusing one across calls with a fresh rgba or a changed
+// ab.fg would keep drawing onto the previous call's buffer in the previous
+// call's color.
+func (ab *AvatarBuilder) buildDrawContext(rgba *image.RGBA) error {
+	f, err := ab.loadFont()
 	if err != nil {
-		return errors.New("error when parse font file:" + err.Error())
+		return err
 	}
 
 	c := freetype.NewContext()
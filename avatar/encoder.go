@@ -0,0 +1,86 @@
+package avatarbuilder
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Encoder turns a rendered avatar image into an output format. AvatarBuilder
+// defaults to PNGEncoder{} unless SetEncoder overrides it.
+type Encoder interface {
+	Encode(w io.Writer, img image.Image) error
+	// Extension returns the filename extension (including the leading dot)
+	// conventionally used for this encoder's output, e.g. ".png".
+	Extension() string
+}
+
+// PNGEncoder writes img as PNG, matching GenerateImage's historical default.
+type PNGEncoder struct {
+	CompressionLevel png.CompressionLevel
+}
+
+func (e PNGEncoder) Encode(w io.Writer, img image.Image) error {
+	enc := png.Encoder{CompressionLevel: e.CompressionLevel}
+	return enc.Encode(w, img)
+}
+
+func (e PNGEncoder) Extension() string {
+	return ".png"
+}
+
+// JPEGEncoder writes img as JPEG at the given Quality (1-100, 0 means
+// jpeg.DefaultQuality), trading fidelity for the smaller payloads
+// user-uploaded avatar endpoints favor.
+type JPEGEncoder struct {
+	Quality int
+}
+
+func (e JPEGEncoder) Encode(w io.Writer, img image.Image) error {
+	quality := e.Quality
+	if quality == 0 {
+		quality = jpeg.DefaultQuality
+	}
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+func (e JPEGEncoder) Extension() string {
+	return ".jpg"
+}
+
+// WebPEncoder is a stub: the standard library has no WebP encoder, so
+// callers must wire in a third-party implementation (e.g. chai2010/webp)
+// by replacing it with their own Encoder via SetEncoder.
+type WebPEncoder struct{}
+
+func (e WebPEncoder) Encode(w io.Writer, img image.Image) error {
+	return errors.New("webp encode: no encoder configured, pass a custom Encoder to SetEncoder")
+}
+
+func (e WebPEncoder) Extension() string {
+	return ".webp"
+}
+
+// SetEncoder overrides the format used by GenerateImage, GenerateMultilineImage
+// and GenerateImageAndSave. The default is PNGEncoder{}.
+func (ab *AvatarBuilder) SetEncoder(enc Encoder) {
+	ab.encoder = enc
+}
+
+// encode runs img through ab.encoder, defaulting to PNGEncoder{} if none was set.
+func (ab *AvatarBuilder) encode(img image.Image) ([]byte, error) {
+	enc := ab.encoder
+	if enc == nil {
+		enc = PNGEncoder{}
+	}
+
+	buf := &bytes.Buffer{}
+	if err := enc.Encode(buf, img); err != nil {
+		return nil, errors.New("encode image: " + err.Error())
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+package avatarbuilder
+
+import (
+	"bytes"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJPEGEncoderRoundTrip(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	ab.SetEncoder(JPEGEncoder{Quality: 80})
+
+	bs, err := ab.GenerateImage("AB")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := jpeg.Decode(bytes.NewReader(bs))
+	if err != nil {
+		t.Fatalf("decode jpeg: %v", err)
+	}
+	if img.Bounds().Dx() != ab.W || img.Bounds().Dy() != ab.H {
+		t.Fatalf("expected %dx%d, got %dx%d", ab.W, ab.H, img.Bounds().Dx(), img.Bounds().Dy())
+	}
+}
+
+func TestPNGEncoderCompressionLevelStillDecodable(t *testing.T) {
+	for _, level := range []png.CompressionLevel{png.NoCompression, png.BestSpeed, png.BestCompression} {
+		ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+		ab.SetEncoder(PNGEncoder{CompressionLevel: level})
+
+		bs, err := ab.GenerateImage("AB")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := png.Decode(bytes.NewReader(bs)); err != nil {
+			t.Fatalf("decode png at compression level %d: %v", level, err)
+		}
+	}
+}
+
+func TestWebPEncoderReturnsStubError(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	ab.SetEncoder(WebPEncoder{})
+
+	if _, err := ab.GenerateImage("AB"); err == nil {
+		t.Fatal("expected WebPEncoder to return an error, got nil")
+	}
+}
+
+func TestGenerateImageAndSaveAppendsEncoderExtension(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	ab.SetEncoder(JPEGEncoder{Quality: 80})
+
+	outBase := filepath.Join(t.TempDir(), "avatar")
+	if err := ab.GenerateImageAndSave("AB", outBase); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(outBase + ".jpg"); err != nil {
+		t.Fatalf("expected %s.jpg to exist: %v", outBase, err)
+	}
+	if _, err := os.Stat(outBase); err == nil {
+		t.Fatalf("expected no file written without the inferred extension")
+	}
+}
+
+func TestGenerateImageAndSaveKeepsExplicitExtension(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	ab.SetEncoder(JPEGEncoder{Quality: 80})
+
+	outPath := filepath.Join(t.TempDir(), "avatar.png")
+	if err := ab.GenerateImageAndSave("AB", outPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(outPath); err != nil {
+		t.Fatalf("expected explicit extension %s to be preserved: %v", outPath, err)
+	}
+}
@@ -0,0 +1,93 @@
+package avatarbuilder
+
+import (
+	"hash/fnv"
+	"image/color"
+	"strings"
+	"unicode"
+)
+
+// defaultPalette is the built-in set of background colors used by
+// GenerateInitialsAvatar when no custom palette has been set via SetPalette.
+var defaultPalette = []color.Color{
+	color.RGBA{R: 0xE5, G: 0x73, B: 0x73, A: 0xFF},
+	color.RGBA{R: 0xF0, G: 0x6F, B: 0x4A, A: 0xFF},
+	color.RGBA{R: 0xF5, G: 0xB0, B: 0x41, A: 0xFF},
+	color.RGBA{R: 0x9C, G: 0xCC, B: 0x65, A: 0xFF},
+	color.RGBA{R: 0x4D, G: 0xB6, B: 0xAC, A: 0xFF},
+	color.RGBA{R: 0x4F, G: 0xC3, B: 0xF7, A: 0xFF},
+	color.RGBA{R: 0x64, G: 0x8B, B: 0xE0, A: 0xFF},
+	color.RGBA{R: 0x95, G: 0x75, B: 0xCD, A: 0xFF},
+	color.RGBA{R: 0xBA, G: 0x68, B: 0xC8, A: 0xFF},
+	color.RGBA{R: 0xF0, G: 0x6F, B: 0x92, A: 0xFF},
+	color.RGBA{R: 0x7C, G: 0xB3, B: 0x42, A: 0xFF},
+	color.RGBA{R: 0x4F, G: 0x5B, B: 0x93, A: 0xFF},
+}
+
+// SetPalette replaces the background color palette used by GenerateInitialsAvatar.
+func (ab *AvatarBuilder) SetPalette(palette []color.Color) {
+	ab.palette = palette
+}
+
+// GenerateInitialsAvatar renders a Gravatar-style fallback avatar: 1-2 initials
+// extracted from name, drawn over a background color deterministically picked
+// from ab's palette (defaultPalette unless SetPalette was called), with a
+// foreground color auto-selected for contrast.
+func (ab *AvatarBuilder) GenerateInitialsAvatar(name string) ([]byte, error) {
+	initials := extractInitials(name)
+
+	palette := ab.palette
+	if len(palette) == 0 {
+		palette = defaultPalette
+	}
+	bg := palette[paletteIndex(name, len(palette))]
+
+	ab.bg = bg
+	ab.fg = contrastColor(bg)
+
+	return ab.GenerateImage(initials)
+}
+
+// extractInitials returns up to two uppercased initials from name: the first
+// rune of the first token and the first rune of the last token. A single-token
+// name yields just its first rune.
+func extractInitials(name string) string {
+	tokens := strings.Fields(name)
+	if len(tokens) == 0 {
+		return ""
+	}
+
+	first := unicode.ToUpper(firstRune(tokens[0]))
+	if len(tokens) == 1 {
+		return string(first)
+	}
+
+	last := unicode.ToUpper(firstRune(tokens[len(tokens)-1]))
+	return string(first) + string(last)
+}
+
+func firstRune(s string) rune {
+	for _, r := range s {
+		return r
+	}
+	return 0
+}
+
+// paletteIndex hashes s with FNV-32 and maps it onto [0, n) so the same input
+// always selects the same palette entry.
+func paletteIndex(s string, n int) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.ToLower(strings.TrimSpace(s))))
+	return int(h.Sum32() % uint32(n))
+}
+
+// contrastColor picks white or black depending on the perceived luminance of
+// bg, so initials stay legible against any palette color.
+func contrastColor(bg color.Color) color.Color {
+	r, g, b, _ := bg.RGBA()
+	luminance := 0.299*float64(r>>8) + 0.587*float64(g>>8) + 0.114*float64(b>>8)
+	if luminance > 186 {
+		return color.Black
+	}
+	return color.White
+}
@@ -0,0 +1,72 @@
+package avatarbuilder
+
+import (
+	"bytes"
+	"testing"
+)
+
+type centerCalcTest struct{}
+
+func (centerCalcTest) CalculateCenterLocation(s string, ab *AvatarBuilder) (int, int) {
+	return ab.W / 4, ab.H / 2
+}
+
+func TestGenerateInitialsAvatarRepeatedCallsDiffer(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+
+	a, err := ab.GenerateInitialsAvatar("Alice Anderson")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ab.GenerateInitialsAvatar("Bob Brown")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(a, b) {
+		t.Fatal("second GenerateInitialsAvatar call on a reused builder returned identical bytes to the first")
+	}
+}
+
+func TestExtractInitials(t *testing.T) {
+	cases := []struct {
+		name string
+		want string
+	}{
+		{"Ada Lovelace", "AL"},
+		{"Madonna", "M"},
+		{"  Grace   Hopper  ", "GH"},
+		{"étoile filante", "ÉF"},
+		{"", ""},
+		{"   ", ""},
+	}
+
+	for _, c := range cases {
+		if got := extractInitials(c.name); got != c.want {
+			t.Errorf("extractInitials(%q) = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPaletteIndexIsDeterministicAndDistributed(t *testing.T) {
+	names := []string{
+		"Alice Anderson", "Bob Brown", "Carol Clark", "Dave Davis",
+		"Eve Evans", "Frank Foster", "Grace Green", "Heidi Hall",
+	}
+
+	seen := make(map[int]bool)
+	for _, name := range names {
+		idx := paletteIndex(name, len(defaultPalette))
+		if idx < 0 || idx >= len(defaultPalette) {
+			t.Fatalf("paletteIndex(%q) = %d, out of range [0,%d)", name, idx, len(defaultPalette))
+		}
+		if got := paletteIndex(name, len(defaultPalette)); got != idx {
+			t.Fatalf("paletteIndex(%q) not deterministic: %d != %d", name, idx, got)
+		}
+		seen[idx] = true
+	}
+
+	if len(seen) < 2 {
+		t.Fatalf("expected distinct names to spread across multiple palette entries, all %d mapped to %v", len(names), seen)
+	}
+}
@@ -0,0 +1,132 @@
+package avatarbuilder
+
+import (
+	"errors"
+	"image"
+	"strings"
+
+	"github.com/golang/freetype"
+	"github.com/golang/freetype/truetype"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// LineCenterCalculator is an optional extension of FontCenterCalculator:
+// implement it to take over line layout for GenerateMultilineImage instead of
+// the default centering/stacking behavior. Calculators that don't implement
+// it keep working unchanged, one CalculateCenterLocation call per line.
+type LineCenterCalculator interface {
+	CalculateLineLocations(lines []string, ab *AvatarBuilder) []image.Point
+}
+
+// GenerateMultilineImage renders s across one or more lines: explicit "\n"
+// breaks are honored, and any resulting line wider than ab.W minus twice
+// ab.textPadding is word-wrapped to fit; a single word wider than that is not
+// split and overflows on its own line. Lines are stacked using
+// ab.lineHeightMultiple * ab.fontSize spacing.
+func (ab *AvatarBuilder) GenerateMultilineImage(s string) ([]byte, error) {
+	rgba := ab.buildColorImage()
+	if err := ab.buildDrawContext(rgba); err != nil {
+		return nil, err
+	}
+
+	face, err := ab.newFace()
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	lines := ab.wrapLines(s, face)
+
+	var points []image.Point
+	if lc, ok := ab.calc.(LineCenterCalculator); ok {
+		points = lc.CalculateLineLocations(lines, ab)
+	} else {
+		points = ab.defaultLineLocations(lines)
+	}
+
+	for i, line := range lines {
+		if i >= len(points) {
+			break
+		}
+		pt := freetype.Pt(points[i].X, points[i].Y)
+		if _, err := ab.ctx.DrawString(line, pt); err != nil {
+			return nil, errors.New("draw string: " + err.Error())
+		}
+	}
+
+	ab.applyShape(rgba)
+
+	return ab.encode(rgba)
+}
+
+// defaultLineLocations centers each line horizontally via the builder's
+// FontCenterCalculator and stacks lines vertically around the avatar's
+// midpoint, so single-line input renders exactly as GenerateImage would.
+func (ab *AvatarBuilder) defaultLineLocations(lines []string) []image.Point {
+	lineHeight := int(ab.fontSize * ab.lineHeightMultiple)
+	startY := -(lineHeight * (len(lines) - 1)) / 2
+
+	points := make([]image.Point, len(lines))
+	for i, line := range lines {
+		x, y := ab.calc.CalculateCenterLocation(line, ab)
+		points[i] = image.Point{X: x, Y: y + startY + i*lineHeight}
+	}
+
+	return points
+}
+
+func (ab *AvatarBuilder) newFace() (font.Face, error) {
+	f, err := ab.loadFont()
+	if err != nil {
+		return nil, err
+	}
+
+	return truetype.NewFace(f, &truetype.Options{
+		Size:    ab.fontSize,
+		DPI:     72,
+		Hinting: font.HintingNone,
+	}), nil
+}
+
+// wrapLines splits s on explicit newlines, then greedily word-wraps each
+// resulting paragraph so no multi-word line exceeds ab.W minus 2*ab.textPadding.
+// Words are never split, so a single word wider than that stays on its own line.
+func (ab *AvatarBuilder) wrapLines(s string, face font.Face) []string {
+	maxWidth := fixed.I(ab.W - 2*ab.textPadding)
+
+	var lines []string
+	for _, paragraph := range strings.Split(s, "\n") {
+		words := strings.Fields(paragraph)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		current := words[0]
+		for _, word := range words[1:] {
+			candidate := current + " " + word
+			if lineWidth(face, candidate) <= maxWidth {
+				current = candidate
+				continue
+			}
+			lines = append(lines, current)
+			current = word
+		}
+		lines = append(lines, current)
+	}
+
+	return lines
+}
+
+func lineWidth(face font.Face, s string) fixed.Int26_6 {
+	var width fixed.Int26_6
+	for _, r := range s {
+		adv, ok := face.GlyphAdvance(r)
+		if !ok {
+			continue
+		}
+		width += adv
+	}
+	return width
+}
@@ -0,0 +1,54 @@
+package avatarbuilder
+
+import (
+	"bytes"
+	"testing"
+
+	"golang.org/x/image/math/fixed"
+)
+
+func TestGenerateMultilineImageRepeatedCallsDiffer(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+
+	a, err := ab.GenerateMultilineImage("hello\nworld")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ab.GenerateMultilineImage("foo\nbar")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(a, b) {
+		t.Fatal("second GenerateMultilineImage call on a reused builder returned identical bytes to the first")
+	}
+}
+
+func TestWrapLinesRespectsExplicitNewlinesAndWraps(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	ab.SetAvatarSize(120, 120)
+	ab.SetFontSize(24)
+	ab.SetTextPadding(4)
+
+	face, err := ab.newFace()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer face.Close()
+
+	lines := ab.wrapLines("aaaa bbbb cccc\ndd", face)
+
+	if len(lines) < 3 {
+		t.Fatalf("expected the explicit newline plus word-wrap to produce at least 3 lines, got %v", lines)
+	}
+	if lines[len(lines)-1] != "dd" {
+		t.Fatalf("expected the last line to be the second paragraph %q unsplit, got lines %v", "dd", lines)
+	}
+
+	maxWidth := fixed.I(ab.W - 2*ab.textPadding)
+	for _, line := range lines {
+		if w := lineWidth(face, line); w > maxWidth {
+			t.Errorf("line %q has width %v, exceeds max width %v", line, w, maxWidth)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package avatarbuilder
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"math"
+)
+
+// Shape determines the mask applied to the avatar before it is encoded.
+type Shape interface {
+	// mask returns an alpha mask the size of bounds, shrunk inward by inset
+	// pixels: fully opaque inside the shape, fully transparent outside, with
+	// a 1px anti-aliased edge. inset is 0 for the avatar's own mask and equal
+	// to the border width when carving out the ring SetBorder strokes into.
+	mask(bounds image.Rectangle, inset float64) *image.Alpha
+}
+
+// ShapeSquare leaves the avatar's corners untouched (the default).
+type ShapeSquare struct{}
+
+func (ShapeSquare) mask(bounds image.Rectangle, inset float64) *image.Alpha {
+	mask := image.NewAlpha(bounds)
+	inner := image.Rect(
+		bounds.Min.X+int(inset), bounds.Min.Y+int(inset),
+		bounds.Max.X-int(inset), bounds.Max.Y-int(inset),
+	)
+	draw.Draw(mask, inner, image.NewUniform(color.Opaque), image.Point{}, draw.Src)
+	return mask
+}
+
+// ShapeCircle masks the avatar to the largest circle that fits its bounds.
+type ShapeCircle struct{}
+
+func (ShapeCircle) mask(bounds image.Rectangle, inset float64) *image.Alpha {
+	cx, cy := float64(bounds.Dx())/2, float64(bounds.Dy())/2
+	r := math.Min(cx, cy) - inset
+
+	mask := image.NewAlpha(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			d := distance(float64(x)+0.5, float64(y)+0.5, cx, cy)
+			mask.SetAlpha(x, y, color.Alpha{A: edgeCoverage(d, r)})
+		}
+	}
+	return mask
+}
+
+// ShapeRoundedRect masks the avatar's corners to the given pixel Radius.
+type ShapeRoundedRect struct {
+	Radius int
+}
+
+func (s ShapeRoundedRect) mask(bounds image.Rectangle, inset float64) *image.Alpha {
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	left, top := inset, inset
+	right, bottom := w-inset, h-inset
+	r := float64(s.Radius) - inset
+	if r < 0 {
+		r = 0
+	}
+
+	mask := image.NewAlpha(bounds)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			fx, fy := float64(x)+0.5, float64(y)+0.5
+			if fx < left || fx > right || fy < top || fy > bottom {
+				continue // outside the inset rect: leave the zero-value alpha
+			}
+
+			a := uint8(255)
+			switch {
+			case fx < left+r && fy < top+r:
+				a = edgeCoverage(distance(fx, fy, left+r, top+r), r)
+			case fx > right-r && fy < top+r:
+				a = edgeCoverage(distance(fx, fy, right-r, top+r), r)
+			case fx < left+r && fy > bottom-r:
+				a = edgeCoverage(distance(fx, fy, left+r, bottom-r), r)
+			case fx > right-r && fy > bottom-r:
+				a = edgeCoverage(distance(fx, fy, right-r, bottom-r), r)
+			}
+
+			mask.SetAlpha(x, y, color.Alpha{A: a})
+		}
+	}
+	return mask
+}
+
+func distance(x, y, cx, cy float64) float64 {
+	return math.Hypot(x-cx, y-cy)
+}
+
+// edgeCoverage returns full opacity up to half a pixel inside radius r, full
+// transparency from half a pixel past it, and a linear ramp in between for a
+// 1px anti-aliased edge.
+func edgeCoverage(d, r float64) uint8 {
+	switch {
+	case d <= r-0.5:
+		return 255
+	case d >= r+0.5:
+		return 0
+	default:
+		return uint8(255 * (r + 0.5 - d))
+	}
+}
+
+// SetShape sets the mask applied to the avatar before encoding. The default
+// is ShapeSquare{} (no mask).
+func (ab *AvatarBuilder) SetShape(shape Shape) {
+	ab.shape = shape
+}
+
+// SetBorder strokes the avatar's shape outline with the given pixel width and color.
+func (ab *AvatarBuilder) SetBorder(width int, c color.Color) {
+	ab.borderWidth = width
+	ab.borderColor = c
+}
+
+// applyShape masks rgba to ab.shape (defaulting to ShapeSquare{}, a no-op) and,
+// if SetBorder was called, strokes its outline in place.
+func (ab *AvatarBuilder) applyShape(rgba *image.RGBA) {
+	shape := ab.shape
+	if shape == nil {
+		shape = ShapeSquare{}
+	}
+
+	outer := shape.mask(rgba.Bounds(), 0)
+	masked := image.NewRGBA(rgba.Bounds())
+	draw.DrawMask(masked, rgba.Bounds(), rgba, image.Point{}, outer, image.Point{}, draw.Over)
+	*rgba = *masked
+
+	if ab.borderWidth > 0 {
+		ab.strokeBorder(rgba, shape, outer)
+	}
+}
+
+// strokeBorder paints ab.borderColor into the ring between outer and a mask
+// inset by ab.borderWidth, i.e. the shape's outline.
+func (ab *AvatarBuilder) strokeBorder(rgba *image.RGBA, shape Shape, outer *image.Alpha) {
+	inner := shape.mask(rgba.Bounds(), float64(ab.borderWidth))
+	border := image.NewUniform(ab.borderColor)
+
+	bounds := rgba.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			a := outer.AlphaAt(x, y).A
+			if a == 0 || inner.AlphaAt(x, y).A > 0 {
+				continue
+			}
+			draw.DrawMask(rgba, image.Rect(x, y, x+1, y+1), border, image.Point{}, outer, image.Point{X: x, Y: y}, draw.Over)
+		}
+	}
+}
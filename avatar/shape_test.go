@@ -0,0 +1,68 @@
+package avatarbuilder
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestShapeRoundedRectBorderCoversStraightEdges(t *testing.T) {
+	ab := NewAvatarBuilderWithEmbeddedFont(centerCalcTest{})
+	ab.SetBackGroundColor(color.White)
+	ab.SetShape(ShapeRoundedRect{Radius: 30})
+	ab.SetBorder(4, color.Black)
+
+	rgba := ab.buildColorImage()
+	if err := ab.buildDrawContext(rgba); err != nil {
+		t.Fatal(err)
+	}
+	ab.applyShape(rgba)
+
+	// Mid-width, near the top edge, well clear of the rounded corners.
+	x, y := ab.W/2, 2
+	r, g, b, _ := rgba.At(x, y).RGBA()
+	if r>>8 > 50 || g>>8 > 50 || b>>8 > 50 {
+		t.Fatalf("expected border color at straight-edge pixel (%d,%d), got rgb(%d,%d,%d)", x, y, r>>8, g>>8, b>>8)
+	}
+}
+
+func TestShapeCircleMaskGeometry(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 100)
+	mask := ShapeCircle{}.mask(bounds, 0)
+
+	if a := mask.AlphaAt(50, 50).A; a != 255 {
+		t.Errorf("expected fully opaque center pixel, got alpha %d", a)
+	}
+	if a := mask.AlphaAt(0, 0).A; a != 0 {
+		t.Errorf("expected fully transparent corner pixel, got alpha %d", a)
+	}
+	if a := mask.AlphaAt(99, 0).A; a != 0 {
+		t.Errorf("expected fully transparent corner pixel, got alpha %d", a)
+	}
+}
+
+func TestShapeRoundedRectMaskGeometry(t *testing.T) {
+	bounds := image.Rect(0, 0, 100, 100)
+	mask := ShapeRoundedRect{Radius: 20}.mask(bounds, 0)
+
+	if a := mask.AlphaAt(0, 0).A; a != 0 {
+		t.Errorf("expected fully transparent corner pixel outside the radius, got alpha %d", a)
+	}
+	if a := mask.AlphaAt(50, 2).A; a != 255 {
+		t.Errorf("expected fully opaque straight-edge pixel, got alpha %d", a)
+	}
+	if a := mask.AlphaAt(2, 50).A; a != 255 {
+		t.Errorf("expected fully opaque straight-edge pixel, got alpha %d", a)
+	}
+	if a := mask.AlphaAt(50, 50).A; a != 255 {
+		t.Errorf("expected fully opaque center pixel, got alpha %d", a)
+	}
+
+	// Inset greater than Radius should clamp the corner radius to 0, making
+	// the inset rect's corners themselves fully opaque right up to the edge.
+	inset := mask
+	inset = ShapeRoundedRect{Radius: 5}.mask(bounds, 10)
+	if a := inset.AlphaAt(10, 10).A; a != 255 {
+		t.Errorf("expected inset > Radius to clamp corner radius to 0, got alpha %d at inset corner", a)
+	}
+}